@@ -11,7 +11,9 @@ import (
 
 func main() {
 	// Create a custom log handler
-	handler := sloghandler.NewLogFormatHandler(slog.LevelDebug, os.Stdout)
+	handler := sloghandler.NewLogFormatHandler(os.Stdout, &sloghandler.HandlerOptions{
+		Level: slog.LevelDebug,
+	})
 
 	// Set up the logger with the custom handler
 	logger := slog.New(handler)
@@ -0,0 +1,268 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+)
+
+// encoder renders one format (the default log-style text, logfmt, or JSON).
+// coreHandler drives an encoder through the level/attrs/group/AddSource/
+// ReplaceAttr plumbing shared by LogFormatHandler, LogfmtHandler, and
+// JSONHandler.
+type encoder interface {
+	// encodeHeader writes the record's time, level, message, and (if
+	// AddSource is set) source. A zero Attr (Key == "") means that
+	// built-in was dropped by ReplaceAttr, or was never computed (source
+	// when AddSource is false), and must be omitted. source is written
+	// here, rather than through encodeAttr, so it is always a top-level
+	// field alongside time/level/msg, never nested inside whatever group
+	// is open on the handler.
+	encodeHeader(buf *bytes.Buffer, timeAttr, levelAttr, msgAttr, sourceAttr slog.Attr)
+
+	// appendPreformatted appends pre, the bytes a previous encodeAttr
+	// batch (from a WithAttrs call) produced, inserting whatever
+	// separator the format needs between what's already in buf and pre.
+	appendPreformatted(buf *bytes.Buffer, pre []byte)
+
+	// encodeAttr writes a, nested under groups (outermost first). open
+	// is how many of groups are already open in buf (from a prior call
+	// in the same batch, or from preformatted attrs); encodeAttr opens
+	// any remaining groups before writing a and returns the resulting
+	// number of open groups.
+	encodeAttr(buf *bytes.Buffer, groups []string, open int, a slog.Attr) int
+
+	// end closes the open remaining groups, if any, and terminates the
+	// line.
+	end(buf *bytes.Buffer, open int)
+}
+
+// sink holds the output writer and its lock, shared by pointer across a
+// coreHandler and every handler derived from it via WithAttrs/WithGroup, so
+// that clones serialize writes through the same mutex instead of each
+// acquiring its own copy.
+type sink struct {
+	mu     sync.RWMutex // Protects concurrent writes to writer.
+	writer io.Writer
+}
+
+// coreHandler implements the slog.Handler methods shared by every handler
+// in this package; each exported handler type wraps one configured with a
+// different encoder.
+type coreHandler struct {
+	opts       HandlerOptions
+	sink       *sink
+	vmod       *vmoduleState
+	newEncoder func() encoder
+
+	// preformatted holds attrs already committed by a prior WithAttrs
+	// call, already encoded (including any group nesting they opened).
+	preformatted []byte
+	// groups is the full WithGroup chain for this handler. Entries
+	// beyond nOpenGroups are "pending": they have not been written to
+	// preformatted because no attrs have been added under them yet, and
+	// per slog.Handler semantics must be elided if none ever are.
+	groups      []string
+	nOpenGroups int
+}
+
+// level returns the minimum level this handler currently processes,
+// consulting opts.Level on every call so a shared *slog.LevelVar is
+// honored.
+func (h *coreHandler) level() slog.Level {
+	if h.opts.Level == nil {
+		return slog.LevelInfo
+	}
+	return h.opts.Level.Level()
+}
+
+// replaceAttr applies opts.ReplaceAttr to a, if set.
+func (h *coreHandler) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if h.opts.ReplaceAttr == nil {
+		return a
+	}
+	return h.opts.ReplaceAttr(groups, a)
+}
+
+// source returns the "file:line" source attribute for pc, or a zero Attr if
+// pc has no associated source information.
+func (h *coreHandler) source(pc uintptr) slog.Attr {
+	if pc == 0 {
+		return slog.Attr{}
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return slog.Attr{}
+	}
+
+	return slog.String(slog.SourceKey, fmt.Sprintf("%s:%d", frame.File, frame.Line))
+}
+
+// enabled reports whether level is processed by this handler's base level.
+// If Vmodule rules are configured, enabled always returns true: a rule
+// might raise the effective level for a specific call site above the base
+// level, and that can only be resolved once a record's PC is known, in
+// handle.
+func (h *coreHandler) enabled(level slog.Level) bool {
+	if h.vmod.hasRules() {
+		return true
+	}
+	return level >= h.level()
+}
+
+// effectiveLevel returns the minimum level that applies to a record logged
+// from pc: the first matching Vmodule rule's level, or the handler's base
+// level if none match.
+func (h *coreHandler) effectiveLevel(pc uintptr) slog.Level {
+	if lvl, ok := h.vmod.levelFor(pc); ok {
+		return lvl
+	}
+	return h.level()
+}
+
+// setVmodule replaces the handler's Vmodule rules, affecting this handler
+// and every handler derived from it via WithAttrs/WithGroup.
+func (h *coreHandler) setVmodule(spec string) error {
+	return h.vmod.set(spec)
+}
+
+// flattenAttrs resolves LogValuers and inlines the attrs of any Group-kind
+// attr whose key is empty, recursively, per slog.Handler's documented
+// semantics.
+func flattenAttrs(attrs []slog.Attr) []slog.Attr {
+	var out []slog.Attr
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup && a.Key == "" {
+			out = append(out, flattenAttrs(a.Value.Group())...)
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// withAttrs returns a coreHandler with attrs committed to preformatted,
+// under the handler's current group chain.
+func (h *coreHandler) withAttrs(attrs []slog.Attr) *coreHandler {
+	h2 := *h
+
+	attrs = flattenAttrs(attrs)
+	if len(attrs) == 0 {
+		return &h2
+	}
+
+	enc := h.newEncoder()
+	buf := bytes.NewBuffer(append([]byte(nil), h.preformatted...))
+	open := h.nOpenGroups
+	for _, a := range attrs {
+		a = h.replaceAttr(h.groups, a)
+		if a.Key == "" {
+			continue
+		}
+		open = enc.encodeAttr(buf, h.groups, open, a)
+	}
+
+	h2.preformatted = buf.Bytes()
+	h2.nOpenGroups = open
+	return &h2
+}
+
+// withGroup returns a coreHandler with name appended to the group chain.
+func (h *coreHandler) withGroup(name string) *coreHandler {
+	h2 := *h
+	h2.groups = append(append([]string(nil), h.groups...), name)
+	return &h2
+}
+
+// handle formats r and writes it to the handler's writer.
+func (h *coreHandler) handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.effectiveLevel(r.PC) {
+		return nil
+	}
+
+	enc := h.newEncoder()
+	var buf bytes.Buffer
+
+	var timeAttr slog.Attr
+	if !r.Time.IsZero() {
+		timeAttr = h.replaceAttr(nil, slog.Time(slog.TimeKey, r.Time))
+	}
+	levelAttr := h.replaceAttr(nil, slog.Any(slog.LevelKey, r.Level))
+	msgAttr := h.replaceAttr(nil, slog.String(slog.MessageKey, r.Message))
+
+	var sourceAttr slog.Attr
+	if h.opts.AddSource {
+		sourceAttr = h.replaceAttr(nil, h.source(r.PC))
+	}
+
+	enc.encodeHeader(&buf, timeAttr, levelAttr, msgAttr, sourceAttr)
+
+	enc.appendPreformatted(&buf, h.preformatted)
+	open := h.nOpenGroups
+
+	for _, a := range flattenAttrs(attrsOf(r)) {
+		a = h.replaceAttr(h.groups, a)
+		if a.Key != "" {
+			open = enc.encodeAttr(&buf, h.groups, open, a)
+		}
+	}
+
+	enc.end(&buf, open)
+
+	h.sink.mu.Lock()
+	defer h.sink.mu.Unlock()
+	if _, err := h.sink.writer.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write log: %w", err)
+	}
+
+	return nil
+}
+
+// attrsOf collects r's attributes into a slice so they can be flattened.
+func attrsOf(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// formatTime renders v, assumed to hold the record's timestamp, using
+// layout if it is a time.Time; otherwise it falls back to v's default
+// formatting, so that a ReplaceAttr which substitutes a different value
+// still renders sensibly.
+func formatTime(v slog.Value, layout string) string {
+	if v.Kind() == slog.KindTime {
+		return v.Time().Format(layout)
+	}
+	return v.String()
+}
+
+// formatLevel renders v, assumed to hold the record's level.
+func formatLevel(v slog.Value) string {
+	if v.Kind() == slog.KindAny {
+		if lvl, ok := v.Any().(slog.Level); ok {
+			return lvl.String()
+		}
+	}
+	return v.String()
+}
+
+// jsonString returns the JSON encoding of s.
+func jsonString(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		// s is a Go string; Marshal only fails for types it cannot
+		// represent, which cannot happen here.
+		panic(err)
+	}
+	return string(b)
+}
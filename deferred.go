@@ -0,0 +1,163 @@
+package sloghandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultMaxDeferred is the number of records DeferredHandler buffers by
+// default before it starts dropping records and counting the overflow.
+const DefaultMaxDeferred = 1000
+
+// frame records a single WithAttrs or WithGroup call so it can be replayed
+// against a different handler later, mirroring how slog.Handler
+// implementations compose derived handlers.
+type frame struct {
+	attrs []slog.Attr // set for a WithAttrs frame
+	group string      // set for a WithGroup frame
+}
+
+// deferredEntry is one buffered log record, along with the chain of frames
+// that were in effect on the handler that received it.
+type deferredEntry struct {
+	ctx    context.Context
+	record slog.Record
+	frames []frame
+}
+
+// deferredCore holds the state shared by a DeferredHandler and every
+// handler derived from it via WithAttrs/WithGroup, so records recorded
+// through any of them land in the same buffer.
+type deferredCore struct {
+	mu       sync.Mutex
+	max      int
+	entries  []deferredEntry
+	overflow int
+}
+
+// DeferredHandler is a slog.Handler that buffers records, along with their
+// WithAttrs/WithGroup context, instead of formatting them. It is meant to
+// be used as a temporary default handler during early program
+// initialization:
+//
+//	deferred := sloghandler.NewDeferred(0)
+//	slog.SetDefault(slog.New(deferred))
+//	...
+//	handler := sloghandler.NewLogFormatHandler(os.Stdout, nil)
+//	deferred.Flush(handler)
+//	slog.SetDefault(slog.New(handler))
+//
+// so that log calls made before the real handler is configured are not
+// lost.
+type DeferredHandler struct {
+	core   *deferredCore
+	frames []frame
+}
+
+// NewDeferred creates a DeferredHandler that buffers up to max records. If
+// max is <= 0, DefaultMaxDeferred is used.
+func NewDeferred(max int) *DeferredHandler {
+	if max <= 0 {
+		max = DefaultMaxDeferred
+	}
+	return &DeferredHandler{core: &deferredCore{max: max}}
+}
+
+// Enabled always returns true: DeferredHandler buffers every record so
+// that the eventual target handler decides what is enabled.
+func (h *DeferredHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// Handle buffers r, along with this handler's WithAttrs/WithGroup chain,
+// for later replay via Flush. If the buffer is full, r is dropped and
+// counted toward the overflow attribute emitted by Flush.
+func (h *DeferredHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.core.mu.Lock()
+	defer h.core.mu.Unlock()
+
+	if len(h.core.entries) >= h.core.max {
+		h.core.overflow++
+		return nil
+	}
+
+	h.core.entries = append(h.core.entries, deferredEntry{
+		ctx:    ctx,
+		record: r.Clone(),
+		frames: append([]frame(nil), h.frames...),
+	})
+
+	return nil
+}
+
+// WithAttrs returns a new handler sharing this handler's buffer, with
+// attrs recorded so they are reapplied to the target handler on Flush.
+func (h *DeferredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DeferredHandler{
+		core:   h.core,
+		frames: append(append([]frame(nil), h.frames...), frame{attrs: attrs}),
+	}
+}
+
+// WithGroup returns a new handler sharing this handler's buffer, with the
+// group recorded so it is reapplied to the target handler on Flush.
+func (h *DeferredHandler) WithGroup(name string) slog.Handler {
+	return &DeferredHandler{
+		core:   h.core,
+		frames: append(append([]frame(nil), h.frames...), frame{group: name}),
+	}
+}
+
+// Flush first drains the buffer, so a later Flush does not replay the same
+// records again, then replays the drained records into target in the order
+// they were recorded, reapplying each record's WithAttrs/WithGroup chain to
+// target first. A record is skipped if the resulting handler's Enabled
+// reports false for its level. If replaying a record fails, Flush returns
+// the error and the records after it are not replayed; they are no longer
+// buffered either. If records were dropped due to the buffer being full,
+// Flush logs one additional warning record to target with a "dropped"
+// attribute giving the overflow count.
+func (h *DeferredHandler) Flush(target slog.Handler) error {
+	h.core.mu.Lock()
+	entries := h.core.entries
+	overflow := h.core.overflow
+	h.core.entries = nil
+	h.core.overflow = 0
+	h.core.mu.Unlock()
+
+	for _, e := range entries {
+		th := applyFrames(target, e.frames)
+		if !th.Enabled(e.ctx, e.record.Level) {
+			continue
+		}
+		if err := th.Handle(e.ctx, e.record); err != nil {
+			return fmt.Errorf("failed to replay buffered record: %w", err)
+		}
+	}
+
+	if overflow > 0 {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "deferred log buffer overflowed; records were dropped", 0)
+		r.AddAttrs(slog.Int("dropped", overflow))
+		if err := target.Handle(context.Background(), r); err != nil {
+			return fmt.Errorf("failed to report overflow: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// applyFrames reapplies a chain of WithAttrs/WithGroup frames to h,
+// returning the resulting derived handler.
+func applyFrames(h slog.Handler, frames []frame) slog.Handler {
+	for _, f := range frames {
+		if f.group != "" {
+			h = h.WithGroup(f.group)
+		} else {
+			h = h.WithAttrs(f.attrs)
+		}
+	}
+	return h
+}
@@ -0,0 +1,188 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDeferredHandler_Flush verifies that buffered records are replayed
+// into the target handler in order, with their WithAttrs/WithGroup chain
+// reapplied.
+func TestDeferredHandler_Flush(t *testing.T) {
+	deferred := NewDeferred(0)
+	logger := slog.New(deferred)
+	logger = logger.With("app", "test")
+
+	logger.Info("first")
+	logger.WithGroup("req").Info("second", "id", 42)
+
+	var buf bytes.Buffer
+	target := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+
+	if err := deferred.Flush(target); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := buf.String()
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Flush() wrote %d lines, want 2: %q", len(lines), got)
+	}
+	if !strings.Contains(lines[0], "first") || !strings.Contains(lines[0], "app=test") {
+		t.Errorf("first line = %q, want message %q and attr %q", lines[0], "first", "app=test")
+	}
+	if !strings.Contains(lines[1], "req.id=42") || !strings.Contains(lines[1], "app=test") {
+		t.Errorf("second line = %q, want attrs %q and %q", lines[1], "app=test", "req.id=42")
+	}
+}
+
+// TestDeferredHandler_FlushDrains verifies that Flush drains the buffer, so
+// a second Flush does not replay the same records again.
+func TestDeferredHandler_FlushDrains(t *testing.T) {
+	deferred := NewDeferred(0)
+	logger := slog.New(deferred)
+	logger.Info("first")
+
+	target := NewLogFormatHandler(io.Discard, &HandlerOptions{Level: slog.LevelInfo})
+	if err := deferred.Flush(target); err != nil {
+		t.Fatalf("first Flush() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	target2 := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+	if err := deferred.Flush(target2); err != nil {
+		t.Fatalf("second Flush() error = %v", err)
+	}
+
+	if got := buf.String(); got != "" {
+		t.Errorf("second Flush() wrote %q, want nothing replayed", got)
+	}
+}
+
+// TestDeferredHandler_Overflow verifies that records beyond the buffer cap
+// are dropped and reported as a single overflow record on Flush.
+func TestDeferredHandler_Overflow(t *testing.T) {
+	deferred := NewDeferred(2)
+	logger := slog.New(deferred)
+
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+	logger.Info("four")
+
+	var buf bytes.Buffer
+	target := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+
+	if err := deferred.Flush(target); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := buf.String()
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Flush() wrote %d lines, want 3 (2 buffered + 1 overflow): %q", len(lines), got)
+	}
+	if !strings.Contains(lines[2], "dropped=2") {
+		t.Errorf("overflow line = %q, want it to contain %q", lines[2], "dropped=2")
+	}
+}
+
+// TestDeferredHandler_Concurrent verifies that DeferredHandler is safe to
+// write to from multiple goroutines both while buffering and, once the
+// real handler is installed, after Flush.
+func TestDeferredHandler_Concurrent(t *testing.T) {
+	deferred := NewDeferred(0)
+	logger := slog.New(deferred)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logger.Info(fmt.Sprintf("buffered %d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	target := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+	if err := deferred.Flush(target); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 20 {
+		t.Fatalf("Flush() wrote %d lines, want 20", len(lines))
+	}
+
+	realLogger := slog.New(target)
+	var wg2 sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg2.Add(1)
+		go func(i int) {
+			defer wg2.Done()
+			realLogger.Info(fmt.Sprintf("direct %d", i))
+		}(i)
+	}
+	wg2.Wait()
+
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 40 {
+		t.Errorf("after direct writes got %d lines, want 40", len(lines))
+	}
+}
+
+// TestDeferredHandler_Enabled verifies that DeferredHandler buffers every
+// level, including Debug.
+func TestDeferredHandler_Enabled(t *testing.T) {
+	deferred := NewDeferred(0)
+	if !deferred.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("Enabled(Debug) = false, want true")
+	}
+
+	logger := slog.New(deferred)
+	logger.Debug("debug message", "t", time.Now())
+
+	var buf bytes.Buffer
+	target := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelDebug})
+	if err := deferred.Flush(target); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "debug message") {
+		t.Errorf("Flush() output = %q, want it to contain the buffered debug message", buf.String())
+	}
+}
+
+// TestDeferredHandler_FlushFiltersBelowTargetLevel verifies that Flush only
+// replays records the target handler's Enabled reports as enabled, rather
+// than writing every buffered record regardless of the target's level.
+func TestDeferredHandler_FlushFiltersBelowTargetLevel(t *testing.T) {
+	deferred := NewDeferred(0)
+	logger := slog.New(deferred)
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Warn("warn message")
+
+	var buf bytes.Buffer
+	target := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelWarn})
+
+	if err := deferred.Flush(target); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := buf.String()
+	if strings.Contains(got, "debug message") || strings.Contains(got, "info message") {
+		t.Errorf("Flush() output = %q, want records below target level filtered out", got)
+	}
+	if !strings.Contains(got, "warn message") {
+		t.Errorf("Flush() output = %q, want it to contain the warn-level record", got)
+	}
+}
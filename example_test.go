@@ -9,7 +9,9 @@ import (
 
 func Example() {
 	// Create a custom log handler
-	handler := sloghandler.NewLogFormatHandler(slog.LevelDebug, os.Stdout, sloghandler.DefaultTimeFormat)
+	handler := sloghandler.NewLogFormatHandler(os.Stdout, &sloghandler.HandlerOptions{
+		Level: slog.LevelDebug,
+	})
 
 	// Set up the logger with the custom handler
 	logger := slog.New(handler)
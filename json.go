@@ -0,0 +1,212 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+)
+
+// JSONHandler is a slog.Handler that writes one JSON object per line, with
+// nested groups rendered as nested objects.
+type JSONHandler struct {
+	core *coreHandler
+}
+
+// jsonEncoder renders one JSON object per record.
+type jsonEncoder struct {
+	timeFormat string
+}
+
+// needComma reports whether a comma must precede the next field or
+// element written to buf, based on its last byte.
+func needComma(buf *bytes.Buffer) bool {
+	b := buf.Bytes()
+	if len(b) == 0 {
+		return false
+	}
+	switch b[len(b)-1] {
+	case '{', '[':
+		return false
+	default:
+		return true
+	}
+}
+
+func (e jsonEncoder) encodeHeader(buf *bytes.Buffer, timeAttr, levelAttr, msgAttr, sourceAttr slog.Attr) {
+	buf.WriteByte('{')
+	field := func(key, val string) {
+		if needComma(buf) {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(jsonString(key))
+		buf.WriteByte(':')
+		buf.WriteString(jsonString(val))
+	}
+	if timeAttr.Key != "" {
+		field(timeAttr.Key, formatTime(timeAttr.Value, e.timeFormat))
+	}
+	if levelAttr.Key != "" {
+		field(levelAttr.Key, formatLevel(levelAttr.Value))
+	}
+	if msgAttr.Key != "" {
+		field(msgAttr.Key, msgAttr.Value.String())
+	}
+	if sourceAttr.Key != "" {
+		field(sourceAttr.Key, sourceAttr.Value.String())
+	}
+}
+
+func (jsonEncoder) appendPreformatted(buf *bytes.Buffer, pre []byte) {
+	if len(pre) == 0 {
+		return
+	}
+	if needComma(buf) {
+		buf.WriteByte(',')
+	}
+	buf.Write(pre)
+}
+
+func (jsonEncoder) encodeAttr(buf *bytes.Buffer, groups []string, open int, a slog.Attr) int {
+	for i := open; i < len(groups); i++ {
+		if needComma(buf) {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(jsonString(groups[i]))
+		buf.WriteString(":{")
+	}
+
+	if needComma(buf) {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(jsonString(a.Key))
+	buf.WriteByte(':')
+	writeJSONValue(buf, a.Value)
+
+	return len(groups)
+}
+
+func (jsonEncoder) end(buf *bytes.Buffer, open int) {
+	for i := 0; i < open; i++ {
+		buf.WriteByte('}')
+	}
+	buf.WriteString("}\n")
+}
+
+// writeJSONFloat writes f's JSON encoding to buf. NaN and +/-Inf have no
+// JSON representation, so they are written as quoted strings instead,
+// keeping each line valid JSON.
+func writeJSONFloat(buf *bytes.Buffer, f float64) {
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(`"NaN"`)
+	case math.IsInf(f, 1):
+		buf.WriteString(`"+Inf"`)
+	case math.IsInf(f, -1):
+		buf.WriteString(`"-Inf"`)
+	default:
+		fmt.Fprintf(buf, "%v", f)
+	}
+}
+
+// writeJSONValue writes v's JSON encoding to buf, rendering Group-kind
+// values as nested objects.
+func writeJSONValue(buf *bytes.Buffer, v slog.Value) {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		buf.WriteByte('{')
+		for _, a := range v.Group() {
+			if a.Key == "" {
+				continue
+			}
+			if needComma(buf) {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(jsonString(a.Key))
+			buf.WriteByte(':')
+			writeJSONValue(buf, a.Value)
+		}
+		buf.WriteByte('}')
+	case slog.KindString:
+		buf.WriteString(jsonString(v.String()))
+	case slog.KindBool:
+		if v.Bool() {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case slog.KindInt64:
+		fmt.Fprintf(buf, "%d", v.Int64())
+	case slog.KindUint64:
+		fmt.Fprintf(buf, "%d", v.Uint64())
+	case slog.KindFloat64:
+		writeJSONFloat(buf, v.Float64())
+	case slog.KindDuration:
+		buf.WriteString(jsonString(v.Duration().String()))
+	case slog.KindTime:
+		buf.WriteString(jsonString(v.Time().Format(DefaultTimeFormat)))
+	default:
+		buf.WriteString(jsonString(v.String()))
+	}
+}
+
+// NewJSONHandler creates a new JSONHandler that writes to w using the given
+// options. If opts is nil, default options are used.
+func NewJSONHandler(w io.Writer, opts *HandlerOptions) *JSONHandler {
+	if opts == nil {
+		opts = &HandlerOptions{}
+	}
+
+	timeFormat := opts.TimeFormat
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
+
+	vmod, err := newVmoduleState(opts.Vmodule)
+	if err != nil {
+		vmod = &vmoduleState{}
+	}
+
+	core := &coreHandler{
+		opts: *opts,
+		sink: &sink{writer: w},
+		vmod: vmod,
+		newEncoder: func() encoder {
+			return jsonEncoder{timeFormat: timeFormat}
+		},
+	}
+	core.opts.TimeFormat = timeFormat
+
+	return &JSONHandler{core: core}
+}
+
+// Handle processes a log record, formats it as JSON, and writes it to the
+// output.
+func (h *JSONHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.core.handle(ctx, r)
+}
+
+// Enabled reports whether the handler processes logs at the given level.
+func (h *JSONHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.core.enabled(level)
+}
+
+// WithAttrs returns a new handler with the given attributes added.
+func (h *JSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &JSONHandler{core: h.core.withAttrs(attrs)}
+}
+
+// WithGroup returns a new handler with the specified group name.
+func (h *JSONHandler) WithGroup(name string) slog.Handler {
+	return &JSONHandler{core: h.core.withGroup(name)}
+}
+
+// SetVmodule replaces the handler's Vmodule rules, affecting this handler
+// and every handler derived from it via WithAttrs/WithGroup. See
+// HandlerOptions.Vmodule for the spec syntax.
+func (h *JSONHandler) SetVmodule(spec string) error {
+	return h.core.setVmodule(spec)
+}
@@ -0,0 +1,97 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestJSONHandler_Handle verifies basic JSON formatting, including nested
+// groups and handler-level attributes.
+func TestJSONHandler_Handle(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewJSONHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+	derived := handler.WithAttrs([]slog.Attr{slog.String("app", "testApp")}).(*JSONHandler)
+	derived = derived.WithGroup("req").(*JSONHandler)
+
+	record := slog.NewRecord(time.Date(2025, 1, 11, 12, 0, 0, 0, time.UTC), slog.LevelInfo, "handled", 0)
+	record.AddAttrs(slog.Int("status", 200))
+	if err := derived.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	if got["msg"] != "handled" || got["app"] != "testApp" {
+		t.Errorf("got %#v, want msg=handled and app=testApp", got)
+	}
+	req, ok := got["req"].(map[string]any)
+	if !ok {
+		t.Fatalf("got %#v, want nested \"req\" object", got)
+	}
+	if req["status"] != float64(200) {
+		t.Errorf("req.status = %#v, want 200", req["status"])
+	}
+}
+
+// TestJSONHandler_EmptyGroupElided verifies that a group with no attrs is
+// omitted from the output entirely.
+func TestJSONHandler_EmptyGroupElided(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewJSONHandler(&buf, nil)
+	derived := handler.WithGroup("req").(*JSONHandler)
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "no attrs", 0)
+	if err := derived.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if _, ok := got["req"]; ok {
+		t.Errorf("got %#v, want no \"req\" key", got)
+	}
+}
+
+// TestJSONHandler_NonFiniteFloat verifies that NaN and +/-Inf attribute
+// values, which have no JSON representation, are rendered as quoted
+// strings so each line stays valid JSON.
+func TestJSONHandler_NonFiniteFloat(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		val  float64
+		want string
+	}{
+		{"NaN", math.NaN(), "NaN"},
+		{"+Inf", math.Inf(1), "+Inf"},
+		{"-Inf", math.Inf(-1), "-Inf"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := NewJSONHandler(&buf, nil)
+
+			record := slog.NewRecord(time.Now(), slog.LevelInfo, "non-finite", 0)
+			record.AddAttrs(slog.Float64("val", tc.val))
+			if err := handler.Handle(context.Background(), record); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+			}
+			if got["val"] != tc.want {
+				t.Errorf("val = %#v, want %q", got["val"], tc.want)
+			}
+		})
+	}
+}
@@ -1,122 +1,203 @@
-// Package sloghandler provides a custom slog.Handler implementation that
-// formats log output similarly to the default log package. It supports log
-// levels, attributes, grouping, and ensures thread-safe writes.
+// Package sloghandler provides custom slog.Handler implementations that
+// format log output as plain text (similar to the default log package),
+// logfmt, or JSON. All three support log levels (including a dynamically
+// adjustable *slog.LevelVar), glog-style per-file/per-package verbosity
+// overrides (Vmodule), attributes, nested groups, source locations, and
+// attribute rewriting, and ensure thread-safe writes.
 package sloghandler
 
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"io"
 	"log/slog"
-	"strings"
-	"sync"
 )
 
+// DefaultTimeFormat is the timestamp layout used when HandlerOptions.TimeFormat
+// is empty.
 const DefaultTimeFormat = "2006/01/02 15:04:05"
 
-// LogFormatHandler is a custom log handler that formats logs to similar to
-// the  default log output.
+// HandlerOptions configures a LogFormatHandler, LogfmtHandler, or
+// JSONHandler. It follows the same pattern as slog.HandlerOptions.
+type HandlerOptions struct {
+	// Level reports the minimum record level that will be logged.
+	// The handler calls Level() on every call to Enabled, so passing a
+	// *slog.LevelVar allows the level to be changed dynamically, even
+	// for handlers already returned by WithAttrs/WithGroup. If nil,
+	// the handler uses slog.LevelInfo.
+	Level slog.Leveler
+
+	// TimeFormat is the layout used to format the record's timestamp.
+	// If empty, DefaultTimeFormat is used.
+	TimeFormat string
+
+	// AddSource causes the handler to compute the source code position
+	// of the log statement from the record's PC and add it to the
+	// output as a "source" attribute formatted as "file:line".
+	AddSource bool
+
+	// ReplaceAttr, if non-nil, is called on every attribute before it is
+	// formatted, including the built-in time, level, message, and
+	// source attributes. It follows the calling convention of
+	// slog.HandlerOptions.ReplaceAttr: groups is the list of enclosing
+	// group names (always empty for the built-in attributes), and the
+	// returned Attr replaces a. Returning a zero Attr drops it.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+	// Vmodule is a glog-style per-file/per-package verbosity override,
+	// given as a comma-separated list of "pattern=level" rules, e.g.
+	// "server=DEBUG,rpc/*=INFO,foo.go=WARN". For each record, the
+	// level of the first rule whose pattern matches the logging call
+	// site's file basename (with and without its ".go" suffix), its
+	// enclosing directory name, or that directory name joined to the
+	// basename (e.g. "rpc/server.go" or "rpc/server") overrides Level
+	// for that record. Patterns use
+	// path.Match glob syntax. A record with no matching rule falls back
+	// to Level. If Vmodule is invalid, it is ignored as if empty; use
+	// SetVmodule on the handler to be notified of a parse error.
+	Vmodule string
+}
+
+// LogFormatHandler is a custom log handler that formats logs similar to the
+// default log output: "time LEVEL message key=value ...".
 type LogFormatHandler struct {
-	level      slog.Level   // Min log level that this handler processes.
-	writer     io.Writer    // Destination for log messages.
-	attrs      []slog.Attr  // Additional attrs included in every log entry.
-	group      string       // Optional name for grouping log messages.
-	timeFormat string       // Format for timestamps in log messages.
-	mu         sync.RWMutex // Protects concurrent writes to the log output.
+	core *coreHandler
 }
 
-// Handle processes a log record, formats it, and writes it to the output.
-func (h *LogFormatHandler) Handle(ctx context.Context, r slog.Record) error {
-	if r.Level < h.level {
-		return nil
+// textEncoder renders the LogFormatHandler format: time, level, and message
+// as plain fields, followed by dot-prefixed "key=value" attributes.
+type textEncoder struct {
+	timeFormat string
+}
+
+func (e textEncoder) encodeHeader(buf *bytes.Buffer, timeAttr, levelAttr, msgAttr, sourceAttr slog.Attr) {
+	first := true
+	field := func(s string) {
+		if !first {
+			buf.WriteByte(' ')
+		}
+		first = false
+		buf.WriteString(s)
+	}
+	if timeAttr.Key != "" {
+		field(formatTime(timeAttr.Value, e.timeFormat))
+	}
+	if levelAttr.Key != "" {
+		field(formatLevel(levelAttr.Value))
+	}
+	if msgAttr.Key != "" {
+		field(msgAttr.Value.String())
+	}
+	if sourceAttr.Key != "" {
+		field(sourceAttr.Key + "=" + sourceAttr.Value.String())
 	}
+}
 
-	var buf bytes.Buffer
+func (textEncoder) appendPreformatted(buf *bytes.Buffer, pre []byte) {
+	buf.Write(pre)
+}
 
-	// Format timestamp, level, and message
-	buf.WriteString(r.Time.Format(h.timeFormat))
-	buf.WriteString(" ")
-	buf.WriteString(strings.ToUpper(r.Level.String()))
-	buf.WriteString(" ")
+func (e textEncoder) encodeAttr(buf *bytes.Buffer, groups []string, open int, a slog.Attr) int {
+	e.writeAttr(buf, groups, a)
+	return len(groups)
+}
 
-	if h.group != "" {
-		buf.WriteString("[")
-		buf.WriteString(h.group)
-		buf.WriteString("] ")
+// writeAttr writes " key=value" for a, expanding a Group-kind value into
+// its own attrs under groups+a.Key so that a group passed as an attribute
+// (e.g. slog.Group("G", ...)) renders the same as one built via WithGroup.
+func (e textEncoder) writeAttr(buf *bytes.Buffer, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(append([]string(nil), groups...), a.Key)
+		}
+		for _, sub := range a.Value.Group() {
+			if sub.Key != "" {
+				e.writeAttr(buf, groups, sub)
+			}
+		}
+		return
 	}
 
-	buf.WriteString(r.Message)
+	buf.WriteByte(' ')
+	for _, g := range groups {
+		buf.WriteString(g)
+		buf.WriteByte('.')
+	}
+	buf.WriteString(a.Key)
+	buf.WriteByte('=')
+	buf.WriteString(a.Value.String())
+}
 
-	// Append record attributes
-	r.Attrs(func(a slog.Attr) bool {
-		if a.Key != "" {
-			buf.WriteString(fmt.Sprintf(" %s=%v", a.Key, a.Value))
-		}
-		return true
-	})
+func (textEncoder) end(buf *bytes.Buffer, open int) {
+	buf.WriteByte('\n')
+}
 
-	// Append handler-level attributes
-	for _, attr := range h.attrs {
-		if attr.Key != "" {
-			buf.WriteString(fmt.Sprintf(" %s=%v", attr.Key, attr.Value))
-		}
+// NewLogFormatHandler creates a new LogFormatHandler that writes to w using
+// the given options. If opts is nil, default options are used.
+func NewLogFormatHandler(w io.Writer, opts *HandlerOptions) *LogFormatHandler {
+	if opts == nil {
+		opts = &HandlerOptions{}
 	}
 
-	buf.WriteString("\n")
+	timeFormat := opts.TimeFormat
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
 
-	// Write the log with thread safety
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	if _, err := h.writer.Write(buf.Bytes()); err != nil {
-		return fmt.Errorf("failed to write log: %w", err)
+	vmod, err := newVmoduleState(opts.Vmodule)
+	if err != nil {
+		vmod = &vmoduleState{}
 	}
 
-	return nil
+	core := &coreHandler{
+		opts: *opts,
+		sink: &sink{writer: w},
+		vmod: vmod,
+		newEncoder: func() encoder {
+			return textEncoder{timeFormat: timeFormat}
+		},
+	}
+	core.opts.TimeFormat = timeFormat
+
+	return &LogFormatHandler{core: core}
+}
+
+// NewLogFormatHandlerLevel creates a new LogFormatHandler with the given
+// static log level, writer, and timestamp format.
+//
+// Deprecated: use NewLogFormatHandler with a HandlerOptions instead, which
+// supports a dynamic level via *slog.LevelVar, AddSource, and ReplaceAttr.
+func NewLogFormatHandlerLevel(level slog.Level, writer io.Writer, timeFormat string) *LogFormatHandler {
+	return NewLogFormatHandler(writer, &HandlerOptions{
+		Level:      level,
+		TimeFormat: timeFormat,
+	})
+}
+
+// Handle processes a log record, formats it, and writes it to the output.
+func (h *LogFormatHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.core.handle(ctx, r)
 }
 
 // Enabled reports whether the handler processes logs at the given level.
 func (h *LogFormatHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return level >= h.level
+	return h.core.enabled(level)
 }
 
 // WithAttrs returns a new handler with the given attributes added.
 func (h *LogFormatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	newAttrs := append(h.attrs, attrs...)
-	return &LogFormatHandler{
-		level:      h.level,
-		writer:     h.writer,
-		attrs:      newAttrs,
-		group:      h.group,
-		timeFormat: h.timeFormat,
-		mu:         h.mu,
-	}
+	return &LogFormatHandler{core: h.core.withAttrs(attrs)}
 }
 
 // WithGroup returns a new handler with the specified group name.
 func (h *LogFormatHandler) WithGroup(name string) slog.Handler {
-	return &LogFormatHandler{
-		level:      h.level,
-		writer:     h.writer,
-		attrs:      h.attrs,
-		group:      name,
-		timeFormat: h.timeFormat,
-		mu:         h.mu,
-	}
+	return &LogFormatHandler{core: h.core.withGroup(name)}
 }
 
-// NewLogFormatHandler creates a new LogFormatHandler with the given log level,
-// writer, and timestamp format.
-func NewLogFormatHandler(level slog.Level, writer io.Writer, timeFormat string) *LogFormatHandler {
-	if timeFormat == "" {
-		timeFormat = DefaultTimeFormat
-	}
-
-	return &LogFormatHandler{
-		level:      level,
-		writer:     writer,
-		timeFormat: timeFormat,
-	}
+// SetVmodule replaces the handler's Vmodule rules, affecting this handler
+// and every handler derived from it via WithAttrs/WithGroup. See
+// HandlerOptions.Vmodule for the spec syntax.
+func (h *LogFormatHandler) SetVmodule(spec string) error {
+	return h.core.setVmodule(spec)
 }
@@ -62,7 +62,7 @@ func TestLogFormatHandler_Handle(t *testing.T) {
 			wantOutput: "",
 		},
 		{
-			name:  "Grouped log message",
+			name:  "Empty group is elided",
 			level: slog.LevelInfo,
 			record: slog.NewRecord(
 				time.Now(),
@@ -71,7 +71,19 @@ func TestLogFormatHandler_Handle(t *testing.T) {
 				0,
 			),
 			group:           "TestGroup",
-			wantOutput:      "INFO [TestGroup] Grouped message\n",
+			wantOutput:      "INFO Grouped message\n",
+			removeTimestamp: true,
+		},
+		{
+			name:  "Grouped attribute uses a dotted prefix",
+			level: slog.LevelInfo,
+			record: func() slog.Record {
+				r := slog.NewRecord(time.Now(), slog.LevelInfo, "Grouped message", 0)
+				r.AddAttrs(slog.Int("id", 7))
+				return r
+			}(),
+			group:           "TestGroup",
+			wantOutput:      "INFO Grouped message TestGroup.id=7\n",
 			removeTimestamp: true,
 		},
 		{
@@ -92,14 +104,14 @@ func TestLogFormatHandler_Handle(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			handler := NewLogFormatHandler(tc.level, &buf, DefaultTimeFormat)
+			var handler slog.Handler = NewLogFormatHandler(&buf, &HandlerOptions{Level: tc.level})
 
 			// Apply grouping and attributes if specified
 			if tc.group != "" {
-				handler = handler.WithGroup(tc.group).(*LogFormatHandler)
+				handler = handler.WithGroup(tc.group)
 			}
 			if len(tc.attrs) > 0 {
-				handler = handler.WithAttrs(tc.attrs).(*LogFormatHandler)
+				handler = handler.WithAttrs(tc.attrs)
 			}
 
 			// Call Handle
@@ -141,7 +153,7 @@ func TestLogFormatHandler_Enabled(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			handler := NewLogFormatHandler(tc.handlerLv, os.Stdout, DefaultTimeFormat)
+			handler := NewLogFormatHandler(os.Stdout, &HandlerOptions{Level: tc.handlerLv})
 			if got := handler.Enabled(context.Background(), tc.recordLv); got != tc.want {
 				t.Errorf("Enabled() = %v, want %v", got, tc.want)
 			}
@@ -149,15 +161,127 @@ func TestLogFormatHandler_Enabled(t *testing.T) {
 	}
 }
 
+// TestLogFormatHandler_LevelVar verifies that a shared *slog.LevelVar lets
+// the log level be changed dynamically, including on handlers already
+// derived via WithAttrs/WithGroup.
+func TestLogFormatHandler_LevelVar(t *testing.T) {
+	var levelVar slog.LevelVar // defaults to LevelInfo
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{Level: &levelVar})
+	derived := handler.WithAttrs([]slog.Attr{slog.String("app", "test")}).(*LogFormatHandler)
+
+	ctx := context.Background()
+	debugRecord := slog.NewRecord(time.Now(), slog.LevelDebug, "debug message", 0)
+
+	if derived.Enabled(ctx, slog.LevelDebug) {
+		t.Fatalf("Enabled() = true before raising verbosity, want false")
+	}
+	if err := derived.Handle(ctx, debugRecord); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("Handle() wrote output at disabled level: %q", buf.String())
+	}
+
+	levelVar.Set(slog.LevelDebug)
+
+	if !derived.Enabled(ctx, slog.LevelDebug) {
+		t.Fatalf("Enabled() = false after lowering level via shared LevelVar, want true")
+	}
+	if err := derived.Handle(ctx, debugRecord); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "debug message") {
+		t.Errorf("Handle() output = %q, want it to contain %q", buf.String(), "debug message")
+	}
+}
+
+// TestLogFormatHandler_AddSource verifies that AddSource emits a
+// "source=file:line" attribute derived from the record's PC.
+func TestLogFormatHandler_AddSource(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:     slog.LevelInfo,
+		AddSource: true,
+	})
+
+	logger := slog.New(handler)
+	logger.Info("with source")
+
+	got := buf.String()
+	if !strings.Contains(got, "source=") {
+		t.Errorf("Handle() output = %q, want it to contain a source attribute", got)
+	}
+	if !strings.Contains(got, "log_test.go:") {
+		t.Errorf("Handle() output = %q, want source to reference log_test.go", got)
+	}
+}
+
+// TestLogFormatHandler_AddSourceWithGroup verifies that source stays a
+// top-level field, not nested under an open WithGroup, since
+// HandlerOptions.ReplaceAttr documents groups as always empty for it.
+func TestLogFormatHandler_AddSourceWithGroup(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:     slog.LevelInfo,
+		AddSource: true,
+	})
+	derived := handler.WithGroup("g").(*LogFormatHandler)
+
+	logger := slog.New(derived)
+	logger.Info("with source", "a", "b")
+
+	got := buf.String()
+	if !strings.Contains(got, " source=") {
+		t.Errorf("Handle() output = %q, want a top-level source attribute", got)
+	}
+	if strings.Contains(got, "g.source=") {
+		t.Errorf("Handle() output = %q, want source not nested under group %q", got, "g")
+	}
+	if !strings.Contains(got, "g.a=b") {
+		t.Errorf("Handle() output = %q, want g.a=b", got)
+	}
+}
+
+// TestLogFormatHandler_ReplaceAttr verifies that ReplaceAttr can rewrite an
+// attribute's value and drop built-in attributes such as the timestamp.
+func TestLogFormatHandler_ReplaceAttr(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.Attr{} // drop the timestamp
+			case "password":
+				return slog.String("password", "REDACTED")
+			}
+			return a
+		},
+	})
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "login", 0)
+	record.AddAttrs(slog.String("password", "hunter2"))
+
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	wantOutput := "INFO login password=REDACTED\n"
+	if buf.String() != wantOutput {
+		t.Errorf("ReplaceAttr output mismatch\nGot:  %q\nWant: %q", buf.String(), wantOutput)
+	}
+}
+
 // TestLogFormatHandler_WithAttrs verifies that attributes persist across instances.
 func TestLogFormatHandler_WithAttrs(t *testing.T) {
 	var buf bytes.Buffer
-	handler := NewLogFormatHandler(slog.LevelInfo, &buf, DefaultTimeFormat)
-	handler = handler.WithAttrs([]slog.Attr{slog.String("app", "testApp")}).(*LogFormatHandler)
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+	derived := handler.WithAttrs([]slog.Attr{slog.String("app", "testApp")}).(*LogFormatHandler)
 
 	// Log a message
 	record := slog.NewRecord(time.Now(), slog.LevelInfo, "Attribute test", 0)
-	_ = handler.Handle(context.Background(), record)
+	_ = derived.Handle(context.Background(), record)
 
 	gotOutput := removeTimestamp(buf.String())
 	wantOutput := "INFO Attribute test app=testApp\n"
@@ -166,27 +290,64 @@ func TestLogFormatHandler_WithAttrs(t *testing.T) {
 	}
 }
 
-// TestLogFormatHandler_WithGroup verifies that group names are correctly applied.
+// TestLogFormatHandler_WithGroup verifies that group names prefix
+// subsequent attribute keys, and are elided entirely if no attrs are ever
+// added under them.
 func TestLogFormatHandler_WithGroup(t *testing.T) {
 	var buf bytes.Buffer
-	handler := NewLogFormatHandler(slog.LevelInfo, &buf, DefaultTimeFormat)
-	handler = handler.WithGroup("MyGroup").(*LogFormatHandler)
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+	derived := handler.WithGroup("MyGroup").(*LogFormatHandler)
 
-	// Log a message
 	record := slog.NewRecord(time.Now(), slog.LevelInfo, "Grouped log", 0)
-	_ = handler.Handle(context.Background(), record)
+	_ = derived.Handle(context.Background(), record)
+
+	gotOutput := removeTimestamp(buf.String())
+	wantOutput := "INFO Grouped log\n"
+	if gotOutput != wantOutput {
+		t.Errorf("WithGroup() with no attrs output mismatch\nGot:  %q\nWant: %q", gotOutput, wantOutput)
+	}
+
+	buf.Reset()
+	record = slog.NewRecord(time.Now(), slog.LevelInfo, "Grouped log", 0)
+	record.AddAttrs(slog.String("id", "42"))
+	_ = derived.Handle(context.Background(), record)
+
+	gotOutput = removeTimestamp(buf.String())
+	wantOutput = "INFO Grouped log MyGroup.id=42\n"
+	if gotOutput != wantOutput {
+		t.Errorf("WithGroup() with attrs output mismatch\nGot:  %q\nWant: %q", gotOutput, wantOutput)
+	}
+}
+
+// TestLogFormatHandler_MixedGroupsAndAttrs verifies that a handler built by
+// interleaving WithGroup and WithAttrs calls prefixes each committed attr
+// with every enclosing group it was added under, and that a trailing,
+// never-populated group is elided.
+func TestLogFormatHandler_MixedGroupsAndAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+	derived := handler.
+		WithGroup("a").
+		WithAttrs([]slog.Attr{slog.String("x", "1")}).
+		WithGroup("b").
+		WithAttrs([]slog.Attr{slog.String("y", "2")}).
+		WithGroup("c").(*LogFormatHandler)
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "Mixed log", 0)
+	record.AddAttrs(slog.String("z", "3"))
+	_ = derived.Handle(context.Background(), record)
 
 	gotOutput := removeTimestamp(buf.String())
-	wantOutput := "INFO [MyGroup] Grouped log\n"
+	wantOutput := "INFO Mixed log a.x=1 a.b.y=2 a.b.c.z=3\n"
 	if gotOutput != wantOutput {
-		t.Errorf("WithGroup() output mismatch\nGot:  %q\nWant: %q", gotOutput, wantOutput)
+		t.Errorf("mixed WithGroup/WithAttrs output mismatch\nGot:  %q\nWant: %q", gotOutput, wantOutput)
 	}
 }
 
 // TestLogFormatHandler_Concurrent verifies thread safety for concurrent logging.
 func TestLogFormatHandler_Concurrent(t *testing.T) {
 	var buf bytes.Buffer
-	handler := NewLogFormatHandler(slog.LevelInfo, &buf, DefaultTimeFormat)
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
 
 	var wg sync.WaitGroup
 	for i := 0; i < 10; i++ {
@@ -0,0 +1,183 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"unicode"
+)
+
+// LogfmtHandler is a slog.Handler that writes records in logfmt
+// (https://brandur.org/logfmt) form: space-separated "key=value" pairs,
+// quoting values that need it.
+type LogfmtHandler struct {
+	core *coreHandler
+}
+
+// logfmtEncoder renders one logfmt line per record.
+type logfmtEncoder struct {
+	timeFormat string
+}
+
+func (e logfmtEncoder) encodeHeader(buf *bytes.Buffer, timeAttr, levelAttr, msgAttr, sourceAttr slog.Attr) {
+	first := true
+	field := func(key, val string) {
+		if !first {
+			buf.WriteByte(' ')
+		}
+		first = false
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		writeLogfmtValue(buf, val)
+	}
+	if timeAttr.Key != "" {
+		field(timeAttr.Key, formatTime(timeAttr.Value, e.timeFormat))
+	}
+	if levelAttr.Key != "" {
+		field(levelAttr.Key, formatLevel(levelAttr.Value))
+	}
+	if msgAttr.Key != "" {
+		field(msgAttr.Key, msgAttr.Value.String())
+	}
+	if sourceAttr.Key != "" {
+		field(sourceAttr.Key, sourceAttr.Value.String())
+	}
+}
+
+func (logfmtEncoder) appendPreformatted(buf *bytes.Buffer, pre []byte) {
+	buf.Write(pre)
+}
+
+func (e logfmtEncoder) encodeAttr(buf *bytes.Buffer, groups []string, open int, a slog.Attr) int {
+	e.writeAttr(buf, groups, a)
+	return len(groups)
+}
+
+// writeAttr writes " key=value" for a, expanding a Group-kind value into
+// its own attrs under groups+a.Key so that a group passed as an attribute
+// (e.g. slog.Group("G", ...)) renders the same as one built via WithGroup.
+func (e logfmtEncoder) writeAttr(buf *bytes.Buffer, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(append([]string(nil), groups...), a.Key)
+		}
+		for _, sub := range a.Value.Group() {
+			if sub.Key != "" {
+				e.writeAttr(buf, groups, sub)
+			}
+		}
+		return
+	}
+
+	buf.WriteByte(' ')
+	for _, g := range groups {
+		buf.WriteString(g)
+		buf.WriteByte('.')
+	}
+	buf.WriteString(a.Key)
+	buf.WriteByte('=')
+	writeLogfmtValue(buf, a.Value.String())
+}
+
+func (logfmtEncoder) end(buf *bytes.Buffer, open int) {
+	buf.WriteByte('\n')
+}
+
+// writeLogfmtValue writes s to buf, quoting it if it contains a space, an
+// '=', a '"', or a control character, and escaping embedded quotes and
+// backslashes.
+func writeLogfmtValue(buf *bytes.Buffer, s string) {
+	if !needsLogfmtQuote(s) {
+		buf.WriteString(s)
+		return
+	}
+
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func needsLogfmtQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewLogfmtHandler creates a new LogfmtHandler that writes to w using the
+// given options. If opts is nil, default options are used.
+func NewLogfmtHandler(w io.Writer, opts *HandlerOptions) *LogfmtHandler {
+	if opts == nil {
+		opts = &HandlerOptions{}
+	}
+
+	timeFormat := opts.TimeFormat
+	if timeFormat == "" {
+		timeFormat = DefaultTimeFormat
+	}
+
+	vmod, err := newVmoduleState(opts.Vmodule)
+	if err != nil {
+		vmod = &vmoduleState{}
+	}
+
+	core := &coreHandler{
+		opts: *opts,
+		sink: &sink{writer: w},
+		vmod: vmod,
+		newEncoder: func() encoder {
+			return logfmtEncoder{timeFormat: timeFormat}
+		},
+	}
+	core.opts.TimeFormat = timeFormat
+
+	return &LogfmtHandler{core: core}
+}
+
+// Handle processes a log record, formats it as logfmt, and writes it to
+// the output.
+func (h *LogfmtHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.core.handle(ctx, r)
+}
+
+// Enabled reports whether the handler processes logs at the given level.
+func (h *LogfmtHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.core.enabled(level)
+}
+
+// WithAttrs returns a new handler with the given attributes added.
+func (h *LogfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogfmtHandler{core: h.core.withAttrs(attrs)}
+}
+
+// WithGroup returns a new handler with the specified group name.
+func (h *LogfmtHandler) WithGroup(name string) slog.Handler {
+	return &LogfmtHandler{core: h.core.withGroup(name)}
+}
+
+// SetVmodule replaces the handler's Vmodule rules, affecting this handler
+// and every handler derived from it via WithAttrs/WithGroup. See
+// HandlerOptions.Vmodule for the spec syntax.
+func (h *LogfmtHandler) SetVmodule(spec string) error {
+	return h.core.setVmodule(spec)
+}
+
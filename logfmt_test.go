@@ -0,0 +1,56 @@
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLogfmtHandler_Handle verifies basic logfmt formatting, including
+// quoting of values that contain spaces.
+func TestLogfmtHandler_Handle(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogfmtHandler(&buf, &HandlerOptions{Level: slog.LevelInfo})
+
+	record := slog.NewRecord(time.Date(2025, 1, 11, 12, 0, 0, 0, time.UTC), slog.LevelInfo, "handled", 0)
+	record.AddAttrs(slog.String("note", "needs quoting"))
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	want := `time="2025/01/11 12:00:00" level=INFO msg=handled note="needs quoting"` + "\n"
+	if buf.String() != want {
+		t.Errorf("Handle() output mismatch\nGot:  %q\nWant: %q", buf.String(), want)
+	}
+}
+
+// TestLogfmtHandler_QuotesSpecialValues verifies the quoting rules for
+// values containing spaces, '=', and embedded quotes.
+func TestLogfmtHandler_QuotesSpecialValues(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogfmtHandler(&buf, nil)
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	record.AddAttrs(
+		slog.String("plain", "ok"),
+		slog.String("eq", "a=b"),
+		slog.String("quote", `say "hi"`),
+	)
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "plain=ok") {
+		t.Errorf("output = %q, want unquoted plain=ok", got)
+	}
+	if !strings.Contains(got, `eq="a=b"`) {
+		t.Errorf("output = %q, want quoted eq=\"a=b\"", got)
+	}
+	if !strings.Contains(got, `quote="say \"hi\""`) {
+		t.Errorf("output = %q, want escaped quote attr", got)
+	}
+}
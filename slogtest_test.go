@@ -0,0 +1,193 @@
+package sloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+// TestLogFormatHandler_Slogtest runs the standard library's handler
+// conformance suite against LogFormatHandler. TimeFormat is set to a layout
+// with no embedded spaces so the positional time/level/message header can
+// be told apart from the "key=value" attrs that follow it.
+func TestLogFormatHandler_Slogtest(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{TimeFormat: time.RFC3339Nano})
+
+	results := func() []map[string]any {
+		var maps []map[string]any
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			maps = append(maps, parseLogText(line))
+		}
+		return maps
+	}
+
+	if err := slogtest.TestHandler(handler, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// parseLogText parses a line produced by LogFormatHandler back into a
+// map[string]any. The header is positional rather than "key=value", so the
+// leading fields are recovered by position: a line either starts with a
+// formatted slog.Level (if the record's Time was zero and so omitted) or
+// with the formatted time followed by the level; whatever single token
+// follows is the message. Remaining "key=value" tokens are parsed the same
+// way as logfmt, expanding dot-prefixed keys into nested maps so that
+// slogtest can check group membership.
+func parseLogText(line string) map[string]any {
+	m := map[string]any{}
+	tokens := splitLogfmtFields(line)
+
+	i := 0
+	var lvl slog.Level
+	if i < len(tokens) && lvl.UnmarshalText([]byte(tokens[i])) == nil {
+		m[slog.LevelKey] = tokens[i]
+		i++
+	} else if i < len(tokens) {
+		m[slog.TimeKey] = tokens[i]
+		i++
+		if i < len(tokens) {
+			m[slog.LevelKey] = tokens[i]
+			i++
+		}
+	}
+	if i < len(tokens) && !strings.Contains(tokens[i], "=") {
+		m[slog.MessageKey] = tokens[i]
+		i++
+	}
+
+	for _, tok := range tokens[i:] {
+		key, val, ok := strings.Cut(tok, "=")
+		if !ok {
+			continue
+		}
+		val = unquoteLogfmtValue(val)
+		setNested(m, strings.Split(key, "."), val)
+	}
+	return m
+}
+
+// TestJSONHandler_Slogtest runs the standard library's handler conformance
+// suite against JSONHandler.
+func TestJSONHandler_Slogtest(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewJSONHandler(&buf, nil)
+
+	results := func() []map[string]any {
+		var maps []map[string]any
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			var m map[string]any
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				t.Fatalf("invalid JSON line %q: %v", line, err)
+			}
+			maps = append(maps, m)
+		}
+		return maps
+	}
+
+	if err := slogtest.TestHandler(handler, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestLogfmtHandler_Slogtest runs the standard library's handler
+// conformance suite against LogfmtHandler.
+func TestLogfmtHandler_Slogtest(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogfmtHandler(&buf, nil)
+
+	results := func() []map[string]any {
+		var maps []map[string]any
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			maps = append(maps, parseLogfmt(line))
+		}
+		return maps
+	}
+
+	if err := slogtest.TestHandler(handler, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// parseLogfmt parses a line produced by LogfmtHandler back into a
+// map[string]any, expanding dot-prefixed keys into nested maps so that
+// slogtest can check group membership.
+func parseLogfmt(line string) map[string]any {
+	m := map[string]any{}
+	for _, tok := range splitLogfmtFields(line) {
+		key, val, ok := strings.Cut(tok, "=")
+		if !ok {
+			continue
+		}
+		val = unquoteLogfmtValue(val)
+		setNested(m, strings.Split(key, "."), val)
+	}
+	return m
+}
+
+// splitLogfmtFields splits s on spaces that are not inside a double-quoted
+// value.
+func splitLogfmtFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+			cur.WriteByte(c)
+		case c == ' ' && !inQuotes:
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+func unquoteLogfmtValue(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	s = strings.ReplaceAll(s, `\"`, `"`)
+	s = strings.ReplaceAll(s, `\n`, "\n")
+	s = strings.ReplaceAll(s, `\t`, "\t")
+	s = strings.ReplaceAll(s, `\\`, `\`)
+	return s
+}
+
+// setNested sets path (outermost key first) to val within m, creating
+// nested map[string]any values for every key but the last.
+func setNested(m map[string]any, path []string, val any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = val
+}
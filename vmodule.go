@@ -0,0 +1,167 @@
+package sloghandler
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// vmoduleRule is one parsed "pattern=level" entry from a Vmodule spec.
+type vmoduleRule struct {
+	pattern string
+	level   slog.Level
+}
+
+// vmoduleResult is the cached outcome of resolving a PC against the current
+// rules, so repeated calls from the same call site skip the frame walk and
+// pattern matching.
+type vmoduleResult struct {
+	level   slog.Level
+	matched bool
+}
+
+// vmoduleState holds the compiled Vmodule rules shared by a coreHandler and
+// every handler derived from it via WithAttrs/WithGroup, plus a cache of
+// PC-to-level resolutions. A nil *vmoduleState behaves as if no rules are
+// configured.
+type vmoduleState struct {
+	mu    sync.RWMutex
+	rules []vmoduleRule
+	cache atomic.Pointer[sync.Map] // uintptr (pc) -> vmoduleResult
+}
+
+// newVmoduleState parses spec and returns the resulting state. An empty spec
+// is valid and produces a state with no rules.
+func newVmoduleState(spec string) (*vmoduleState, error) {
+	rules, err := parseVmodule(spec)
+	if err != nil {
+		return nil, err
+	}
+	v := &vmoduleState{rules: rules}
+	v.cache.Store(new(sync.Map))
+	return v, nil
+}
+
+// parseVmodule parses a comma-separated "pattern=level" spec, e.g.
+// "server=DEBUG,rpc/*=INFO,foo.go=WARN".
+func parseVmodule(spec string) ([]vmoduleRule, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	rules := make([]vmoduleRule, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		pattern, levelStr, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("sloghandler: invalid vmodule rule %q: missing \"=\"", part)
+		}
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, "x"); err != nil {
+			return nil, fmt.Errorf("sloghandler: invalid vmodule pattern %q: %w", pattern, err)
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(levelStr))); err != nil {
+			return nil, fmt.Errorf("sloghandler: invalid vmodule level in %q: %w", part, err)
+		}
+
+		rules = append(rules, vmoduleRule{pattern: pattern, level: level})
+	}
+
+	return rules, nil
+}
+
+// set replaces v's rules with the result of parsing spec, invalidating the
+// PC-to-level cache so lookups are resolved against the new rules.
+func (v *vmoduleState) set(spec string) error {
+	rules, err := parseVmodule(spec)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.rules = rules
+	v.mu.Unlock()
+	v.cache.Store(new(sync.Map))
+
+	return nil
+}
+
+// hasRules reports whether v has any rules configured. A nil v has none.
+func (v *vmoduleState) hasRules() bool {
+	if v == nil {
+		return false
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return len(v.rules) > 0
+}
+
+// levelFor resolves pc against v's rules, returning the first match's level
+// and true, or false if no rule matches. Results are cached per PC.
+func (v *vmoduleState) levelFor(pc uintptr) (slog.Level, bool) {
+	if v == nil || pc == 0 {
+		return 0, false
+	}
+
+	cache := v.cache.Load()
+	if cache == nil {
+		// A zero vmoduleState (used for an invalid Vmodule spec) has no
+		// rules and no cache yet.
+		return 0, false
+	}
+	if cached, ok := cache.Load(pc); ok {
+		r := cached.(vmoduleResult)
+		return r.level, r.matched
+	}
+
+	v.mu.RLock()
+	rules := v.rules
+	v.mu.RUnlock()
+
+	level, matched := matchVmoduleRules(rules, pc)
+	cache.Store(pc, vmoduleResult{level: level, matched: matched})
+	return level, matched
+}
+
+// matchVmoduleRules resolves the call site at pc to a file basename (with
+// and without its ".go" suffix) and an enclosing-directory "package" name,
+// and returns the level of the first rule whose pattern, matched with
+// path.Match glob semantics, matches any of them.
+func matchVmoduleRules(rules []vmoduleRule, pc uintptr) (slog.Level, bool) {
+	if len(rules) == 0 {
+		return 0, false
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return 0, false
+	}
+
+	base := filepath.Base(frame.File)
+	baseNoExt := strings.TrimSuffix(base, ".go")
+	pkg := filepath.Base(filepath.Dir(frame.File))
+
+	candidates := []string{base, baseNoExt, pkg, pkg + "/" + base, pkg + "/" + baseNoExt}
+
+	for _, rule := range rules {
+		for _, c := range candidates {
+			if ok, _ := path.Match(rule.pattern, c); ok {
+				return rule.level, true
+			}
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package sloghandler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// logAtSite is a fixed call site used to verify that two Handle calls
+// through the same PC pick up a rule change made via SetVmodule between
+// them, rather than an unexpired cached resolution.
+func logAtSite(logger *slog.Logger) {
+	logger.Info("info message")
+}
+
+// TestVmoduleOverridesBaseLevel verifies that a matching Vmodule rule lets a
+// record through that the base Level would otherwise drop.
+func TestVmoduleOverridesBaseLevel(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:   slog.LevelInfo,
+		Vmodule: "vmodule_test.go=DEBUG",
+	})
+
+	slog.New(handler).Debug("debug message")
+
+	if !strings.Contains(buf.String(), "DEBUG debug message") {
+		t.Errorf("got %q, want a DEBUG record let through by the vmodule rule", buf.String())
+	}
+}
+
+// TestVmoduleFirstMatchWins verifies that when multiple rules match a call
+// site, the first one in the spec applies, regardless of specificity.
+func TestVmoduleFirstMatchWins(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:   slog.LevelInfo,
+		Vmodule: "vmodule_test.go=WARN,vmodule_*=DEBUG",
+	})
+
+	slog.New(handler).Debug("should be dropped")
+	if buf.Len() != 0 {
+		t.Errorf("got %q, want no output: the exact-file rule (WARN) should win over the later glob rule (DEBUG)", buf.String())
+	}
+
+	buf.Reset()
+	if err := handler.SetVmodule("vmodule_*=DEBUG,vmodule_test.go=WARN"); err != nil {
+		t.Fatalf("SetVmodule() error = %v", err)
+	}
+	slog.New(handler).Debug("should be let through")
+	if !strings.Contains(buf.String(), "DEBUG should be let through") {
+		t.Errorf("got %q, want the glob rule (DEBUG), now listed first, to win", buf.String())
+	}
+}
+
+// TestVmoduleSetInvalidatesCache verifies that SetVmodule's effect is
+// visible immediately at a call site already resolved and cached under the
+// old rules.
+func TestVmoduleSetInvalidatesCache(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:   slog.LevelInfo,
+		Vmodule: "vmodule_test.go=ERROR",
+	})
+	logger := slog.New(handler)
+
+	logAtSite(logger)
+	if buf.Len() != 0 {
+		t.Fatalf("got %q, want no output before SetVmodule", buf.String())
+	}
+
+	if err := handler.SetVmodule("vmodule_test.go=DEBUG"); err != nil {
+		t.Fatalf("SetVmodule() error = %v", err)
+	}
+	logAtSite(logger)
+	if !strings.Contains(buf.String(), "info message") {
+		t.Errorf("got %q, want the same call site to log after SetVmodule lowered its level", buf.String())
+	}
+}
+
+// TestVmoduleFallsBackToLevelVar verifies that a record from a call site
+// with no matching rule is still gated by the handler's base Level,
+// including a dynamically adjustable *slog.LevelVar.
+func TestVmoduleFallsBackToLevelVar(t *testing.T) {
+	var buf bytes.Buffer
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:   &level,
+		Vmodule: "nomatch_test.go=DEBUG",
+	})
+	logger := slog.New(handler)
+
+	logger.Info("info message")
+	if buf.Len() != 0 {
+		t.Fatalf("got %q, want no output: no rule matches this call site, so base Level (WARN) applies", buf.String())
+	}
+
+	level.Set(slog.LevelInfo)
+	logger.Info("info message")
+	if !strings.Contains(buf.String(), "info message") {
+		t.Errorf("got %q, want the record through once the LevelVar is lowered", buf.String())
+	}
+}
+
+// TestVmoduleInvalidSpec verifies that an invalid Vmodule spec on
+// HandlerOptions is ignored rather than causing a panic, while SetVmodule
+// surfaces the same error and leaves existing rules in place.
+func TestVmoduleInvalidSpec(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:   slog.LevelInfo,
+		Vmodule: "not-a-valid-rule",
+	})
+	slog.New(handler).Debug("dropped")
+	if buf.Len() != 0 {
+		t.Errorf("got %q, want an invalid Vmodule spec to be ignored, not applied", buf.String())
+	}
+
+	handler2 := NewLogFormatHandler(&buf, &HandlerOptions{
+		Level:   slog.LevelInfo,
+		Vmodule: "vmodule_test.go=DEBUG",
+	})
+	if err := handler2.SetVmodule("also-not-valid"); err == nil {
+		t.Error("SetVmodule() error = nil, want an error for an invalid spec")
+	}
+
+	buf.Reset()
+	slog.New(handler2).Debug("still debug")
+	if !strings.Contains(buf.String(), "DEBUG still debug") {
+		t.Errorf("got %q, want the prior rule to still apply after a failed SetVmodule", buf.String())
+	}
+}